@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"reflect"
+	"strings"
+)
+
+var (
+	studyBackendEnv     string = "STUDY_BACKEND"
+	studyBackendDefault string = studyBackendOpenAI
+	studyBackend        string
+
+	studyBaseURLEnv string = "STUDY_BASE_URL"
+
+	backend Backend
+)
+
+const (
+	studyBackendOpenAI     = "openai"
+	studyBackendCompatible = "compatible"
+)
+
+// Backend is anything that can complete chat prompts, produce embeddings,
+// and transcribe audio. OpenAI is the default, but any OpenAI-compatible
+// endpoint (LocalAI, Ollama, llama.cpp's server, ...) can be used instead by
+// implementing the same request/response shapes behind a different base
+// URL.
+type Backend interface {
+	Complete(ctx context.Context, req *CompletionRequest) *CompletionResponse
+	Stream(ctx context.Context, req *CompletionRequest) <-chan Delta
+	Embed(ctx context.Context, text string) []float64
+	Transcribe(ctx context.Context, req *TranscriptionRequest) *TranscriptionResponse
+}
+
+// httpBackend implements Backend against an OpenAI-compatible HTTP API. It
+// backs both NewOpenAIBackend and NewCompatibleBackend; the two only differ
+// in which URLs and API key they're configured with.
+type httpBackend struct {
+	completionURL    string
+	embeddingURL     string
+	transcriptionURL string
+	apiKey           string
+}
+
+// NewOpenAIBackend talks to the real OpenAI API.
+func NewOpenAIBackend() *httpBackend {
+	return &httpBackend{
+		completionURL:    openAICompletionURL,
+		embeddingURL:     openAIEmbeddingURL,
+		transcriptionURL: openAITranscriptionURL,
+		apiKey:           openAIAPIKey,
+	}
+}
+
+// NewCompatibleBackend talks to any OpenAI-compatible endpoint (LocalAI,
+// Ollama, ...) rooted at baseURL, such as a local llama.cpp-backed server.
+func NewCompatibleBackend(baseURL string) *httpBackend {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return &httpBackend{
+		completionURL:    baseURL + "/v1/chat/completions",
+		embeddingURL:     baseURL + "/v1/embeddings",
+		transcriptionURL: baseURL + "/v1/audio/transcriptions",
+		apiKey:           openAIAPIKey,
+	}
+}
+
+func (b *httpBackend) Complete(ctx context.Context, req *CompletionRequest) *CompletionResponse {
+	completionResponse := NewCompletionResponse()
+
+	fromJSON(doPost(b.completionURL, b.apiKey, toJSON(req)), completionResponse)
+
+	return completionResponse
+}
+
+// Stream is the streaming counterpart to Complete: it sends `stream: true`
+// and pushes each delta onto the returned channel as it arrives, closing the
+// channel when the upstream `data: [DONE]` sentinel is seen or ctx is
+// cancelled.
+func (b *httpBackend) Stream(ctx context.Context, req *CompletionRequest) <-chan Delta {
+	req.Stream = true
+
+	body := doPostStream(b.completionURL, b.apiKey, toJSON(req))
+
+	deltas := make(chan Delta)
+
+	go func() {
+		defer body.Close()
+		defer close(deltas)
+
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+
+			payload := strings.TrimPrefix(line, "data: ")
+			if payload == "[DONE]" {
+				return
+			}
+
+			chunk := streamChunk{}
+			fromJSON([]byte(payload), &chunk)
+
+			if len(chunk.Choices) == 0 {
+				continue
+			}
+
+			select {
+			case deltas <- Delta{Content: chunk.Choices[0].Delta.Content}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return deltas
+}
+
+func (b *httpBackend) Embed(ctx context.Context, text string) []float64 {
+	embeddingRequest := NewEmbeddingRequest(text)
+	embeddingResponse := NewEmbeddingResponse()
+
+	fromJSON(doPost(b.embeddingURL, b.apiKey, toJSON(embeddingRequest)), embeddingResponse)
+
+	return embeddingResponse.Embedding()
+}
+
+func (b *httpBackend) Transcribe(ctx context.Context, req *TranscriptionRequest) *TranscriptionResponse {
+	transcriptionResponse := NewTranscriptionResponse()
+
+	fields := map[string]string{"model": req.Model}
+	fromJSON(doPostMultipart(b.transcriptionURL, b.apiKey, fields, "file", req.FileName, req.Audio), transcriptionResponse)
+
+	return transcriptionResponse
+}
+
+// GenerateCompletionStream is the streaming counterpart to
+// GenerateStructuredCompletion: it constrains the response to the JSON
+// Schema generated from schemaType so the caller can still parse a
+// CitedResponse-shaped result out of the assembled text once the stream
+// closes.
+func GenerateCompletionStream(ctx context.Context, text string, name string, schemaType reflect.Type) <-chan Delta {
+	completionRequest := NewCompletionRequest()
+	completionRequest.AddMessage("system", openAICompletionSystemPrompt)
+	completionRequest.AddMessage("user", text)
+	completionRequest.UseJSONSchema(name, SchemaOf(schemaType))
+
+	return backend.Stream(ctx, completionRequest)
+}
+
+// https://platform.openai.com/docs/api-reference/embeddings?lang=curl
+func GenerateEmbedding(text string) []float64 {
+	return backend.Embed(context.Background(), text)
+}
+
+// GenerateStructuredCompletion is like GenerateCompletion but constrains the
+// response to the JSON Schema generated from schemaType, guaranteeing
+// parseable output instead of relying on the model obeying a JSON template
+// embedded in the prompt.
+func GenerateStructuredCompletion(text string, name string, schemaType reflect.Type) string {
+	completionRequest := NewCompletionRequest()
+	completionRequest.AddMessage("system", openAICompletionSystemPrompt)
+	completionRequest.AddMessage("user", text)
+	completionRequest.UseJSONSchema(name, SchemaOf(schemaType))
+
+	return backend.Complete(context.Background(), completionRequest).Content()
+}
@@ -0,0 +1,97 @@
+package main
+
+import "strings"
+
+// Chunk is one window of text carved out of a reference file, with an
+// approximate rune offset into the file used to anchor it for display.
+type Chunk struct {
+	ID     int
+	Offset int
+	Text   string
+}
+
+// Chunker splits a document into overlapping Chunks of roughly ChunkSize
+// runes, preferring to break on paragraph or sentence boundaries so each
+// excerpt reads naturally instead of being cut mid-word.
+type Chunker struct {
+	ChunkSize    int
+	ChunkOverlap int
+}
+
+func NewChunker(chunkSize, chunkOverlap int) *Chunker {
+	return &Chunker{ChunkSize: chunkSize, ChunkOverlap: chunkOverlap}
+}
+
+// Chunk splits text into overlapping chunks. Paragraphs that fit within
+// ChunkSize are kept whole; paragraphs that don't are split further on
+// sentence boundaries. Consecutive chunks repeat the trailing ChunkOverlap
+// runes of the previous chunk so similarity search doesn't miss a fact that
+// straddles a chunk boundary.
+func (c *Chunker) Chunk(text string) []Chunk {
+	var units []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		if len([]rune(paragraph)) <= c.ChunkSize {
+			units = append(units, paragraph)
+			continue
+		}
+
+		sentences := strings.Split(paragraph, ". ")
+		for i, sentence := range sentences {
+			sentence = strings.TrimSpace(sentence)
+			if sentence == "" {
+				continue
+			}
+			if i < len(sentences)-1 {
+				sentence += "."
+			}
+			units = append(units, sentence)
+		}
+	}
+
+	var chunks []Chunk
+	var builder []rune
+	offset := 0
+	chunkStart := 0
+
+	flush := func() {
+		if len(builder) == 0 {
+			return
+		}
+		chunks = append(chunks, Chunk{ID: len(chunks), Offset: chunkStart, Text: strings.TrimSpace(string(builder))})
+	}
+
+	for _, unit := range units {
+		unitRunes := []rune(unit)
+
+		if len(builder) > 0 && len(builder)+len(unitRunes)+1 > c.ChunkSize {
+			flush()
+
+			overlap := c.ChunkOverlap
+			if overlap > len(builder) {
+				overlap = len(builder)
+			}
+
+			chunkStart = offset - overlap
+			if chunkStart < 0 {
+				chunkStart = 0
+			}
+
+			builder = append([]rune{}, builder[len(builder)-overlap:]...)
+		}
+
+		if len(builder) > 0 {
+			builder = append(builder, ' ')
+		}
+		builder = append(builder, unitRunes...)
+		offset += len(unitRunes) + 1
+	}
+
+	flush()
+
+	return chunks
+}
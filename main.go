@@ -8,33 +8,158 @@ import (
 	"io"
 	"log"
 	"math"
+	"mime/multipart"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 )
 
 const TMPL_INDEX = `
 <html>
 <h1>{{.Title}}</h1>
-<form action="/" method="post">
-<input type="text" name="query"/>
+<form id="query-form" action="/" method="post">
+<input type="text" name="query" id="query-input"/>
 <input type="submit" value="Query"/>
 </form>
 
+<form id="transcribe-form" action="/transcribe" method="post" enctype="multipart/form-data">
+<input type="file" name="audio" accept="audio/*" capture="microphone"/>
+<input type="submit" value="Ask with audio"/>
+</form>
+
 <h2>Response</h2>
+<p id="stream-commentary"></p>
+<ul id="stream-citations"></ul>
+
 {{if .Response}}
 <p>Commentary: {{.Response.Commentary}}</p>
 
 References:
 <ul>
 {{range .Response.Citations}}
-<li>{{.Claim}} {{range .References}}<a href="/references/{{.File}}">{{.File}}</a>{{end}}</li>
+<li>{{.Claim}} {{range .References}}<a href="/references/{{.File}}#chunk{{.ChunkID}}">{{.File}}#{{.ChunkID}}</a>{{end}}</li>
 {{end}}
 </ul>
 
 {{else}}
 <p>Submit a query to get a response.</p>
 {{end}}
+
+<script>
+document.getElementById("query-form").addEventListener("submit", function (event) {
+	event.preventDefault();
+
+	var query = document.getElementById("query-input").value;
+	var commentary = document.getElementById("stream-commentary");
+	var citations = document.getElementById("stream-citations");
+
+	commentary.textContent = "";
+	citations.innerHTML = "";
+
+	// The model streams one JSON object (see CitedResponse), so raw deltas
+	// can't just be appended as they arrive. extractCommentary pulls the
+	// "commentary" string value out of the JSON assembled so far, decoding
+	// it as it grows.
+	function extractCommentary(raw) {
+		var marker = raw.indexOf('"commentary"');
+		if (marker === -1) {
+			return "";
+		}
+
+		var quoteStart = raw.indexOf('"', raw.indexOf(":", marker) + 1);
+		if (quoteStart === -1) {
+			return "";
+		}
+
+		var result = "";
+		for (var i = quoteStart + 1; i < raw.length; i++) {
+			var ch = raw[i];
+
+			if (ch === "\\") {
+				var next = raw[i + 1];
+				if (next === undefined) {
+					break;
+				}
+				if (next === "n") {
+					result += "\n";
+				} else if (next === "t") {
+					result += "\t";
+				} else {
+					result += next;
+				}
+				i++;
+				continue;
+			}
+
+			if (ch === '"') {
+				break;
+			}
+
+			result += ch;
+		}
+
+		return result;
+	}
+
+	fetch("/stream", {
+		method: "POST",
+		headers: {"Content-Type": "application/x-www-form-urlencoded"},
+		body: "query=" + encodeURIComponent(query),
+	}).then(function (response) {
+		var reader = response.body.getReader();
+		var decoder = new TextDecoder();
+		var buffer = "";
+		var raw = "";
+
+		function read() {
+			reader.read().then(function (result) {
+				if (result.done) {
+					return;
+				}
+
+				buffer += decoder.decode(result.value, {stream: true});
+
+				var events = buffer.split("\n\n");
+				buffer = events.pop();
+
+				events.forEach(function (event) {
+					var line = event.replace(/^data: /, "");
+					if (!line) {
+						return;
+					}
+
+					var payload = JSON.parse(line);
+
+					if (payload.citations) {
+						payload.citations.forEach(function (citation) {
+							var li = document.createElement("li");
+							li.appendChild(document.createTextNode(citation.claim + " "));
+
+							(citation.references || []).forEach(function (reference) {
+								var a = document.createElement("a");
+								a.href = "/references/" + reference.file + "#chunk" + reference.chunk_id;
+								a.textContent = reference.file + "#" + reference.chunk_id;
+								li.appendChild(a);
+								li.appendChild(document.createTextNode(" "));
+							});
+
+							citations.appendChild(li);
+						});
+					} else if (payload.content) {
+						raw += payload.content;
+						commentary.textContent = extractCommentary(raw);
+					}
+				});
+
+				return read();
+			});
+		}
+
+		return read();
+	});
+});
+</script>
 </html>
 `
 
@@ -47,12 +172,6 @@ diversify your knowledge on the topic.
 QUERY
 
 {{.Query}}
-
-JSON RESPONSE TEMPLATE
-
-{
-	"queries": ["<query 1>", "<query 2>", "<query 3>"]
-}
 `
 
 const TMPL_PROMPT_RESPONSE = `
@@ -72,22 +191,22 @@ REFERENCES
 - File: {{.File}}
 - Exerpt: {{.Exerpt}}
 {{end}}
+`
 
-JSON RESPONSE TEMPLATE
-
-{
-	"commentary": "<summary commentary without citations>",
-	"citations": [
-		{
-			"claim": "<summary claim 1>",
-			"references": [
-				{"exerpt": "<exerpt>", "file": "<file-name-1.txt>"},
-				{"exerpt": "<exerpt>", "file": "<file-name-2.txt>"},
-				{"exerpt": "<exerpt>", "file": "<file-name-3.txt>"},
-			]
-		},
-	]
-}
+const TMPL_REFERENCE = `
+<html>
+<head>
+<style>
+.chunk:target { background: yellow; }
+</style>
+</head>
+<body>
+<h1>{{.File}}</h1>
+{{range .Chunks}}
+<div class="chunk" id="chunk{{.ID}}">{{.Text}}</div>
+{{end}}
+</body>
+</html>
 `
 
 var (
@@ -108,17 +227,27 @@ var (
 	openAIEmbeddingURL string = "https://api.openai.com/v1/embeddings"
 
 	similarityThreshold float64 = 0.5
+	searchK             int     = 10
+
+	rrfK    int = 60
+	rrfTopN int = 10
+
+	vectorStore     *VectorStore
+	vectorStorePath string = "vectorstore.json"
+	referencesPath  string = "references"
 
-	references     []Reference
-	referencesPath string = "references"
+	chunkSize    int = 800
+	chunkOverlap int = 200
+	chunker          = NewChunker(chunkSize, chunkOverlap)
 
 	templates map[string]*template.Template = make(map[string]*template.Template)
 )
 
 type Reference struct {
 	File      string    `json:"file"`
+	ChunkID   int       `json:"chunk_id" schema:"-"`
 	Exerpt    string    `json:"exerpt"`
-	Embedding []float64 `json:"embedding,omitempty"`
+	Embedding []float64 `json:"embedding,omitempty" schema:"-"`
 }
 
 type QueryExpansionResponse struct {
@@ -135,16 +264,30 @@ type CitedResponse struct {
 	Citations  []Citation `json:"citations"`
 }
 
+// JSONSchemaSpec is the `json_schema` member of a structured-outputs
+// ResponseFormat: https://platform.openai.com/docs/guides/structured-outputs
+type JSONSchemaSpec struct {
+	Name   string      `json:"name"`
+	Strict bool        `json:"strict"`
+	Schema interface{} `json:"schema"`
+}
+
+type ResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *JSONSchemaSpec `json:"json_schema,omitempty"`
+}
+
 type CompletionRequest struct {
 	Model          string              `json:"model"`
 	Messages       []map[string]string `json:"messages"`
-	ResponseFormat map[string]string   `json:"response_format"`
+	ResponseFormat *ResponseFormat     `json:"response_format"`
+	Stream         bool                `json:"stream,omitempty"`
 }
 
 func NewCompletionRequest() *CompletionRequest {
 	return &CompletionRequest{
 		Model:          openAILanguageModel,
-		ResponseFormat: map[string]string{"type": "json_object"},
+		ResponseFormat: &ResponseFormat{Type: "json_object"},
 	}
 }
 
@@ -152,6 +295,20 @@ func (r *CompletionRequest) AddMessage(role, content string) {
 	r.Messages = append(r.Messages, map[string]string{"role": role, "content": content})
 }
 
+// UseJSONSchema constrains the completion's output to schema under name,
+// guaranteeing the model returns parseable JSON matching it instead of
+// relying on the model obeying a JSON template embedded in the prompt.
+func (r *CompletionRequest) UseJSONSchema(name string, schema interface{}) {
+	r.ResponseFormat = &ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &JSONSchemaSpec{
+			Name:   name,
+			Strict: true,
+			Schema: schema,
+		},
+	}
+}
+
 type CompletionChoice struct {
 	Message map[string]string `json:"message"`
 }
@@ -168,6 +325,22 @@ func (r *CompletionResponse) Content() string {
 	return r.Choices[0].Message["content"]
 }
 
+// Delta is one incremental token (or set of tokens) pushed by the OpenAI
+// streaming chat completions API.
+type Delta struct {
+	Content string `json:"content"`
+}
+
+type streamChoice struct {
+	Delta struct {
+		Content string `json:"content"`
+	} `json:"delta"`
+}
+
+type streamChunk struct {
+	Choices []streamChoice `json:"choices"`
+}
+
 type EmbeddingRequest struct {
 	Model string `json:"model"`
 	Input string `json:"input"`
@@ -234,14 +407,102 @@ func fromJSON(data []byte, v interface{}) interface{} {
 	return v
 }
 
-func doPost(url string, body []byte) []byte {
+func doPost(url string, apiKey string, body []byte) []byte {
 	request, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
 	if err != nil {
 		log.Fatalf("failed to create request: %v", err)
 	}
 
 	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", openAIAPIKey))
+	if apiKey != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Fatalf("failed to send request: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		responseBodyBytes, _ := io.ReadAll(response.Body)
+		log.Fatalf("unexpected status code: %v, body: %s", response.StatusCode, string(responseBodyBytes))
+	}
+
+	responseBodyBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		log.Fatalf("failed to read response body: %v", err)
+	}
+
+	return responseBodyBytes
+}
+
+// doPostStream is like doPost but returns the raw response body for a
+// `stream: true` request, which the caller must scan line-by-line for
+// `data: {...}` chunks and close when done.
+func doPostStream(url string, apiKey string, body []byte) io.ReadCloser {
+	request, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		log.Fatalf("failed to create request: %v", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
+	request.Header.Set("Accept", "text/event-stream")
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		log.Fatalf("failed to send request: %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		responseBodyBytes, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		log.Fatalf("unexpected status code: %v, body: %s", response.StatusCode, string(responseBodyBytes))
+	}
+
+	return response.Body
+}
+
+// doPostMultipart is like doPost but sends fields and a single file as a
+// multipart/form-data body, for endpoints (like audio transcription) that
+// don't accept JSON.
+func doPostMultipart(url string, apiKey string, fields map[string]string, fileField, fileName string, fileContent []byte) []byte {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			log.Fatalf("failed to write field %s: %v", key, err)
+		}
+	}
+
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		log.Fatalf("failed to create form file: %v", err)
+	}
+
+	if _, err := part.Write(fileContent); err != nil {
+		log.Fatalf("failed to write file content: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	request, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		log.Fatalf("failed to create request: %v", err)
+	}
+
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	if apiKey != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
 
 	client := &http.Client{}
 	response, err := client.Do(request)
@@ -304,7 +565,12 @@ func CalculateSimilarity(a, b []float64) float64 {
 	return dotProduct / (aMagnitude * bMagnitude)
 }
 
+// LoadReferences populates vectorStore from every file under path, skipping
+// files whose mtime/size hash already matches what's on disk so unchanged
+// references don't get re-embedded on every startup.
 func LoadReferences(path string) {
+	vectorStore = LoadVectorStore(vectorStorePath)
+
 	files, err := os.ReadDir(path)
 	if err != nil {
 		log.Fatalf("failed to read references directory: %v", err)
@@ -315,37 +581,55 @@ func LoadReferences(path string) {
 			continue
 		}
 
+		info, err := file.Info()
+		if err != nil {
+			log.Fatalf("failed to stat file %s: %v", file.Name(), err)
+		}
+
+		hash := hashFileInfo(info)
+		existingHash, known := vectorStore.FileHashes[file.Name()]
+		if known && existingHash == hash {
+			continue
+		}
+
 		content, err := os.ReadFile(path + "/" + file.Name())
 		if err != nil {
 			log.Fatalf("failed to read file %s: %v", file.Name(), err)
 		}
 
-		excerpt := string(content)
-		embedding := GenerateEmbedding(excerpt)
+		if known {
+			vectorStore.Remove(file.Name())
+		}
 
-		reference := Reference{
-			File:      file.Name(),
-			Exerpt:    excerpt,
-			Embedding: embedding,
+		for _, c := range chunker.Chunk(string(content)) {
+			vectorStore.Add(Reference{
+				File:      file.Name(),
+				ChunkID:   c.ID,
+				Exerpt:    c.Text,
+				Embedding: GenerateEmbedding(c.Text),
+			})
 		}
 
-		references = append(references, reference)
+		vectorStore.FileHashes[file.Name()] = hash
 	}
+
+	vectorStore.Save(vectorStorePath)
 }
 
 func FindReference(query string) []Reference {
-	var embedding = GenerateEmbedding(query)
+	embedding := GenerateEmbedding(query)
+
+	seen := make(map[string]bool)
 	var result []Reference
 
-	for _, reference := range references {
-		// TODO(optim) this is O(n^2) and could be optimized
-		for _, r := range result {
-			if r.File == reference.File {
-				continue
-			}
+	for _, reference := range vectorStore.Search(embedding, searchK) {
+		key := fmt.Sprintf("%s#%d", reference.File, reference.ChunkID)
+		if seen[key] {
+			continue
 		}
 
 		if CalculateSimilarity(embedding, reference.Embedding) > similarityThreshold {
+			seen[key] = true
 			result = append(result, reference)
 		}
 	}
@@ -353,33 +637,10 @@ func FindReference(query string) []Reference {
 	return result
 }
 
-// https://platform.openai.com/docs/api-reference/chat/create?lang=curl
-func GenerateCompletion(text string) string {
-	completionRequest := NewCompletionRequest()
-	completionRequest.AddMessage("system", openAICompletionSystemPrompt)
-	completionRequest.AddMessage("user", text)
-
-	completionResponse := NewCompletionResponse()
-
-	fromJSON(doPost(openAICompletionURL, toJSON(completionRequest)), completionResponse)
-
-	return completionResponse.Content()
-}
-
-// https://platform.openai.com/docs/api-reference/embeddings?lang=curl
-func GenerateEmbedding(text string) []float64 {
-	embeddingRequest := NewEmbeddingRequest(text)
-	embeddingResponse := NewEmbeddingResponse()
-
-	fromJSON(doPost(openAIEmbeddingURL, toJSON(embeddingRequest)), embeddingResponse)
-
-	return embeddingResponse.Embedding()
-}
-
 func ExpandQuery(query string) QueryExpansionResponse {
-	response := GenerateCompletion(string(RenderTemplate("expand", map[string]interface{}{
+	response := GenerateStructuredCompletion(string(RenderTemplate("expand", map[string]interface{}{
 		"Query": query,
-	})))
+	})), "query_expansion", reflect.TypeOf(QueryExpansionResponse{}))
 
 	expandedQueryResponse := QueryExpansionResponse{}
 
@@ -389,18 +650,153 @@ func ExpandQuery(query string) QueryExpansionResponse {
 }
 
 func GenerateCompletionWithCitations(query string, references []Reference) CitedResponse {
-	response := GenerateCompletion(string(RenderTemplate("response", map[string]interface{}{
+	response := GenerateStructuredCompletion(string(RenderTemplate("response", map[string]interface{}{
 		"Query":      query,
 		"References": references,
-	})))
+	})), "cited_response", reflect.TypeOf(CitedResponse{}))
 
 	citedResponse := CitedResponse{}
 
 	fromJSON([]byte(response), &citedResponse)
 
+	resolveChunkIDs(citedResponse.Citations, references)
+
 	return citedResponse
 }
 
+// resolveChunkIDs fills in each cited reference's ChunkID by matching it
+// against candidates on (File, Exerpt). ChunkID is tagged schema:"-" so the
+// model never sees it and can't echo it back; without this, every citation
+// link would point at chunk 0 regardless of which chunk was actually cited.
+func resolveChunkIDs(citations []Citation, candidates []Reference) {
+	chunkIDs := make(map[string]int, len(candidates))
+	for _, candidate := range candidates {
+		chunkIDs[candidate.File+"\x00"+candidate.Exerpt] = candidate.ChunkID
+	}
+
+	for i := range citations {
+		for j := range citations[i].References {
+			ref := &citations[i].References[j]
+			if chunkID, ok := chunkIDs[ref.File+"\x00"+ref.Exerpt]; ok {
+				ref.ChunkID = chunkID
+			}
+		}
+	}
+}
+
+// StreamHandler answers a query the same way Handler does, but pushes the
+// model's response to the browser as Server-Sent Events while it is being
+// generated instead of waiting for the full completion. Once the stream
+// closes, the accumulated text is parsed into a CitedResponse and sent as a
+// final event so citations can still be rendered.
+func StreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.FormValue("query")
+
+	var perQueryResults [][]Reference
+	for _, expanded := range ExpandQuery(query).Queries {
+		perQueryResults = append(perQueryResults, FindReference(expanded))
+	}
+
+	references := FuseRankings(perQueryResults, rrfK, rrfTopN)
+
+	prompt := string(RenderTemplate("response", map[string]interface{}{
+		"Query":      query,
+		"References": references,
+	}))
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	var text strings.Builder
+
+	for delta := range GenerateCompletionStream(r.Context(), prompt, "cited_response", reflect.TypeOf(CitedResponse{})) {
+		text.WriteString(delta.Content)
+		fmt.Fprintf(w, "data: %s\n\n", toJSON(delta))
+		flusher.Flush()
+	}
+
+	citedResponse := CitedResponse{}
+	fromJSON([]byte(text.String()), &citedResponse)
+
+	resolveChunkIDs(citedResponse.Citations, references)
+
+	fmt.Fprintf(w, "data: %s\n\n", toJSON(map[string]interface{}{"citations": citedResponse.Citations}))
+	flusher.Flush()
+}
+
+// ReferenceHandler renders a reference file as its constituent chunks, each
+// addressable as #chunkN, so a citation link can jump straight to (and
+// highlight) the excerpt it cited.
+func ReferenceHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/references/")
+
+	content, err := os.ReadFile(referencesPath + "/" + name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Write(RenderTemplate("reference", map[string]interface{}{
+		"File":   name,
+		"Chunks": chunker.Chunk(string(content)),
+	}))
+}
+
+// TranscribeHandler accepts a multipart audio upload, transcribes it via
+// Whisper, and feeds the resulting text through the existing
+// ExpandQuery -> FindReference -> GenerateCompletionWithCitations pipeline,
+// turning the study tool into a spoken-question interface.
+func TranscribeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, "failed to parse multipart form", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("audio")
+	if err != nil {
+		http.Error(w, "missing audio file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	audio, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "failed to read audio", http.StatusInternalServerError)
+		return
+	}
+
+	query := GenerateTranscription(NewTranscriptionRequest(header.Filename, audio))
+
+	var perQueryResults [][]Reference
+	for _, expanded := range ExpandQuery(query).Queries {
+		perQueryResults = append(perQueryResults, FindReference(expanded))
+	}
+
+	references := FuseRankings(perQueryResults, rrfK, rrfTopN)
+
+	w.Write(RenderTemplate("index", map[string]interface{}{
+		"Title":    "Study",
+		"Response": GenerateCompletionWithCitations(query, references),
+	}))
+}
+
 func Handler(w http.ResponseWriter, r *http.Request) {
 	title := "Study"
 
@@ -408,11 +804,13 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		query := r.FormValue("query")
 
-		var references []Reference
-		for _, query := range ExpandQuery(query).Queries {
-			references = append(references, FindReference(query)...)
+		var perQueryResults [][]Reference
+		for _, expanded := range ExpandQuery(query).Queries {
+			perQueryResults = append(perQueryResults, FindReference(expanded))
 		}
 
+		references := FuseRankings(perQueryResults, rrfK, rrfTopN)
+
 		w.Write(RenderTemplate("index", map[string]interface{}{
 			"Title":    title,
 			"Response": GenerateCompletionWithCitations(query, references),
@@ -431,17 +829,31 @@ func main() {
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lmsgprefix)
 	log.Default().SetPrefix("study ")
 
-	openAIAPIKey = mustGetEnv(openAIAPIKeyEnv)
 	openAILanguageModel = getEnv(openAILanguageModelEnv, openAILanguageModelDefault)
 	openAIEmbeddingModel = getEnv(openAIEmbeddingModelEnv, openAIEmbeddingModelDefault)
+	studyBackend = getEnv(studyBackendEnv, studyBackendDefault)
+
+	switch studyBackend {
+	case studyBackendOpenAI:
+		openAIAPIKey = mustGetEnv(openAIAPIKeyEnv)
+		backend = NewOpenAIBackend()
+	case studyBackendCompatible:
+		openAIAPIKey = getEnv(openAIAPIKeyEnv, "")
+		backend = NewCompatibleBackend(mustGetEnv(studyBaseURLEnv))
+	default:
+		log.Fatalln("unknown backend:", studyBackend)
+	}
 
 	LoadTemplate("index", TMPL_INDEX)
 	LoadTemplate("expand", TMPL_PROMPT_EXPAND_QUERY)
 	LoadTemplate("response", TMPL_PROMPT_RESPONSE)
+	LoadTemplate("reference", TMPL_REFERENCE)
 
 	LoadReferences(referencesPath)
 
 	http.HandleFunc("/", Handler)
-	http.Handle("/references/", http.StripPrefix("/references/", http.FileServer(http.Dir("references"))))
+	http.HandleFunc("/stream", StreamHandler)
+	http.HandleFunc("/transcribe", TranscribeHandler)
+	http.HandleFunc("/references/", ReferenceHandler)
 	http.ListenAndServe("127.0.0.1:8080", nil)
 }
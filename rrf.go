@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FuseRankings combines several per-query result rankings into one ranking
+// using Reciprocal Rank Fusion: each document's fused score is the sum of
+// 1/(k+rank) (rank 1-indexed) across every ranking it appears in, so a
+// document that places consistently well across many queries outranks one
+// that only places first in a single query. Returns the topN documents by
+// fused score, deduplicated by (File, ChunkID).
+func FuseRankings(perQueryResults [][]Reference, k int, topN int) []Reference {
+	type fusedReference struct {
+		reference Reference
+		score     float64
+	}
+
+	fusedByKey := make(map[string]*fusedReference)
+
+	for _, rankings := range perQueryResults {
+		for rank, reference := range rankings {
+			key := fmt.Sprintf("%s#%d", reference.File, reference.ChunkID)
+
+			if _, ok := fusedByKey[key]; !ok {
+				fusedByKey[key] = &fusedReference{reference: reference}
+			}
+
+			fusedByKey[key].score += 1.0 / float64(k+rank+1)
+		}
+	}
+
+	fused := make([]fusedReference, 0, len(fusedByKey))
+	for _, f := range fusedByKey {
+		fused = append(fused, *f)
+	}
+
+	sort.Slice(fused, func(i, j int) bool { return fused[i].score > fused[j].score })
+
+	if len(fused) > topN {
+		fused = fused[:topN]
+	}
+
+	result := make([]Reference, len(fused))
+	for i, f := range fused {
+		result[i] = f.reference
+	}
+
+	return result
+}
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestFuseRankingsFavorsConsistentPlacementOverASingleTopRank(t *testing.T) {
+	consistent := Reference{File: "consistent.txt", ChunkID: 0}
+	outlier := Reference{File: "outlier.txt", ChunkID: 0}
+	filler := Reference{File: "filler.txt", ChunkID: 0}
+
+	perQueryResults := [][]Reference{
+		{outlier, consistent},
+		{filler, consistent},
+		{filler, consistent},
+	}
+
+	fused := FuseRankings(perQueryResults, 60, 10)
+
+	if len(fused) == 0 || fused[0].File != "consistent.txt" {
+		t.Fatalf("expected consistent.txt ranked first, got %+v", fused)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaOf generates a JSON Schema object for t by walking its fields via
+// reflection, using each field's `json` tag for the property name. It only
+// covers the subset of Go types this app's structured completions need:
+// structs, slices, strings, bools, and numbers.
+func SchemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		var required []string
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			if field.Tag.Get("schema") == "-" {
+				continue
+			}
+
+			name := field.Name
+			if tag, ok := field.Tag.Lookup("json"); ok {
+				if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+					name = tagName
+				}
+			}
+
+			properties[name] = SchemaOf(field.Type)
+			required = append(required, name)
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": SchemaOf(t.Elem()),
+		}
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
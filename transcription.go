@@ -0,0 +1,35 @@
+package main
+
+import "context"
+
+var (
+	openAITranscriptionURL   string = "https://api.openai.com/v1/audio/transcriptions"
+	openAITranscriptionModel string = "whisper-1"
+)
+
+type TranscriptionRequest struct {
+	Model    string
+	FileName string
+	Audio    []byte
+}
+
+func NewTranscriptionRequest(fileName string, audio []byte) *TranscriptionRequest {
+	return &TranscriptionRequest{
+		Model:    openAITranscriptionModel,
+		FileName: fileName,
+		Audio:    audio,
+	}
+}
+
+type TranscriptionResponse struct {
+	Text string `json:"text"`
+}
+
+func NewTranscriptionResponse() *TranscriptionResponse {
+	return &TranscriptionResponse{}
+}
+
+// https://platform.openai.com/docs/api-reference/audio/createTranscription
+func GenerateTranscription(req *TranscriptionRequest) string {
+	return backend.Transcribe(context.Background(), req).Text
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+const (
+	vectorStoreM              = 16  // max neighbors per node, per layer (doubled at layer 0)
+	vectorStoreEfConstruction = 200 // beam width used while inserting
+	vectorStoreEfSearch       = 64  // beam width used while querying
+)
+
+// vectorStoreNode is one indexed Reference plus its HNSW neighbor lists, one
+// per layer it participates in (every node participates in layer 0).
+type vectorStoreNode struct {
+	Reference Reference `json:"reference"`
+	Layer     int       `json:"layer"`
+	Neighbors [][]int   `json:"neighbors"`
+}
+
+// VectorStore is a persistent approximate-nearest-neighbor index over
+// Reference embeddings, built as a Hierarchical Navigable Small World graph:
+// each node links to its M nearest neighbors at every layer it belongs to.
+// A query descends greedily from the top layer to the entry point of the
+// next layer, then runs a beam search of width efSearch at layer 0.
+type VectorStore struct {
+	Nodes      []*vectorStoreNode `json:"nodes"`
+	EntryPoint int                `json:"entry_point"`
+	FileHashes map[string]string  `json:"file_hashes"`
+}
+
+func NewVectorStore() *VectorStore {
+	return &VectorStore{EntryPoint: -1, FileHashes: make(map[string]string)}
+}
+
+// LoadVectorStore reads a store previously written by Save, or returns an
+// empty store if path doesn't exist yet.
+func LoadVectorStore(path string) *VectorStore {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewVectorStore()
+	}
+	if err != nil {
+		log.Fatalf("failed to load vector store: %v", err)
+	}
+
+	store := NewVectorStore()
+	fromJSON(data, store)
+
+	return store
+}
+
+func (s *VectorStore) Save(path string) {
+	if err := os.WriteFile(path, toJSON(s), 0644); err != nil {
+		log.Fatalf("failed to save vector store: %v", err)
+	}
+}
+
+func hashFileInfo(info os.FileInfo) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", info.Name(), info.Size(), info.ModTime().UnixNano())))
+	return hex.EncodeToString(sum[:])
+}
+
+// distance turns cosine similarity into a distance: 0 for identical vectors,
+// larger for less similar ones.
+func distance(a, b []float64) float64 {
+	return 1 - CalculateSimilarity(a, b)
+}
+
+func randomLevel() int {
+	level := 0
+	for rand.Float64() < 1.0/math.E && level < 16 {
+		level++
+	}
+	return level
+}
+
+type vsCandidate struct {
+	id   int
+	dist float64
+}
+
+func (s *VectorStore) neighborsAt(id, layer int) []int {
+	node := s.Nodes[id]
+	if layer >= len(node.Neighbors) {
+		return nil
+	}
+	return node.Neighbors[layer]
+}
+
+// greedyClosest walks from entry towards target within a single layer,
+// stepping to whichever neighbor is closest until no neighbor improves on
+// the current node.
+func (s *VectorStore) greedyClosest(entry int, target []float64, layer int) int {
+	current := entry
+	currentDist := distance(s.Nodes[current].Reference.Embedding, target)
+
+	for {
+		improved := false
+
+		for _, neighbor := range s.neighborsAt(current, layer) {
+			d := distance(s.Nodes[neighbor].Reference.Embedding, target)
+			if d < currentDist {
+				current, currentDist, improved = neighbor, d, true
+			}
+		}
+
+		if !improved {
+			return current
+		}
+	}
+}
+
+// searchLayer runs a beam search of width ef starting from entry, returning
+// the visited candidates sorted nearest-first.
+func (s *VectorStore) searchLayer(target []float64, entry int, ef int, layer int) []vsCandidate {
+	visited := map[int]bool{entry: true}
+	frontier := []vsCandidate{{entry, distance(s.Nodes[entry].Reference.Embedding, target)}}
+	result := append([]vsCandidate{}, frontier...)
+
+	for len(frontier) > 0 {
+		sort.Slice(frontier, func(i, j int) bool { return frontier[i].dist < frontier[j].dist })
+		current := frontier[0]
+		frontier = frontier[1:]
+
+		sort.Slice(result, func(i, j int) bool { return result[i].dist < result[j].dist })
+		if len(result) >= ef && current.dist > result[ef-1].dist {
+			break
+		}
+
+		for _, neighbor := range s.neighborsAt(current.id, layer) {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+
+			d := distance(s.Nodes[neighbor].Reference.Embedding, target)
+			frontier = append(frontier, vsCandidate{neighbor, d})
+			result = append(result, vsCandidate{neighbor, d})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].dist < result[j].dist })
+	if len(result) > ef {
+		result = result[:ef]
+	}
+
+	return result
+}
+
+// connect adds a bidirectional edge from neighbor to id at layer, pruning
+// id's neighbor list back down to the allowed size if it overflows.
+func (s *VectorStore) connect(id, neighbor, layer int) {
+	node := s.Nodes[id]
+	if layer >= len(node.Neighbors) {
+		return
+	}
+
+	node.Neighbors[layer] = append(node.Neighbors[layer], neighbor)
+
+	maxNeighbors := vectorStoreM
+	if layer == 0 {
+		maxNeighbors = vectorStoreM * 2
+	}
+
+	if len(node.Neighbors[layer]) <= maxNeighbors {
+		return
+	}
+
+	target := node.Reference.Embedding
+	sort.Slice(node.Neighbors[layer], func(i, j int) bool {
+		a := s.Nodes[node.Neighbors[layer][i]].Reference.Embedding
+		b := s.Nodes[node.Neighbors[layer][j]].Reference.Embedding
+		return distance(a, target) < distance(b, target)
+	})
+	node.Neighbors[layer] = node.Neighbors[layer][:maxNeighbors]
+}
+
+// Remove drops every chunk belonging to file and rebuilds the graph from
+// the remaining references. HNSW neighbor lists are positional (indices
+// into Nodes), so a changed or deleted file's chunks can't be patched out
+// in place without invalidating every other node's neighbor list; a full
+// rebuild is the simplest correct way to keep the index free of stale
+// chunks when a reference file changes.
+func (s *VectorStore) Remove(file string) {
+	var kept []Reference
+	for _, node := range s.Nodes {
+		if node.Reference.File != file {
+			kept = append(kept, node.Reference)
+		}
+	}
+
+	s.Nodes = nil
+	s.EntryPoint = -1
+
+	for _, ref := range kept {
+		s.Add(ref)
+	}
+}
+
+// Add inserts ref into the graph, wiring it to its nearest neighbors at
+// every layer from 0 up to a randomly assigned level.
+func (s *VectorStore) Add(ref Reference) {
+	node := &vectorStoreNode{Reference: ref, Layer: randomLevel()}
+	node.Neighbors = make([][]int, node.Layer+1)
+
+	id := len(s.Nodes)
+	s.Nodes = append(s.Nodes, node)
+
+	if s.EntryPoint == -1 {
+		s.EntryPoint = id
+		return
+	}
+
+	entry := s.EntryPoint
+	entryLayer := s.Nodes[entry].Layer
+
+	for layer := entryLayer; layer > node.Layer; layer-- {
+		entry = s.greedyClosest(entry, ref.Embedding, layer)
+	}
+
+	for layer := min(entryLayer, node.Layer); layer >= 0; layer-- {
+		candidates := s.searchLayer(ref.Embedding, entry, vectorStoreEfConstruction, layer)
+
+		neighborCount := vectorStoreM
+		if len(candidates) < neighborCount {
+			neighborCount = len(candidates)
+		}
+
+		neighbors := make([]int, neighborCount)
+		for i := 0; i < neighborCount; i++ {
+			neighbors[i] = candidates[i].id
+		}
+
+		node.Neighbors[layer] = neighbors
+		for _, n := range neighbors {
+			s.connect(n, id, layer)
+		}
+
+		if len(candidates) > 0 {
+			entry = candidates[0].id
+		}
+	}
+
+	if node.Layer > entryLayer {
+		s.EntryPoint = id
+	}
+}
+
+// Search returns the top-k references nearest vec.
+func (s *VectorStore) Search(vec []float64, k int) []Reference {
+	if s.EntryPoint == -1 {
+		return nil
+	}
+
+	entry := s.EntryPoint
+	entryLayer := s.Nodes[entry].Layer
+
+	for layer := entryLayer; layer > 0; layer-- {
+		entry = s.greedyClosest(entry, vec, layer)
+	}
+
+	ef := vectorStoreEfSearch
+	if k > ef {
+		ef = k
+	}
+
+	candidates := s.searchLayer(vec, entry, ef, 0)
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	result := make([]Reference, len(candidates))
+	for i, c := range candidates {
+		result[i] = s.Nodes[c.id].Reference
+	}
+
+	return result
+}